@@ -0,0 +1,69 @@
+// Package checkpoint persists per-run migration progress as a Datastore
+// entity, so a restarted load_data process can skip runs that already
+// finished and resume runs that were interrupted mid-write.
+package checkpoint
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+// State is the lifecycle stage of a single run's migration.
+type State string
+
+// Possible Checkpoint.State values.
+const (
+	StatePending    State = "pending"
+	StateDownloaded State = "downloaded"
+	StateWriting    State = "writing"
+	StateDone       State = "done"
+	StateFailed     State = "failed"
+)
+
+// Checkpoint is the Datastore entity tracking one run's progress.
+type Checkpoint struct {
+	State          State
+	LastRowWritten string
+	Attempts       int
+	LastError      string
+	Updated        time.Time
+}
+
+// Kind is the Datastore kind checkpoints are stored under.
+const Kind = "LoadDataCheckpoint"
+
+// Store reads and writes Checkpoints, keyed by run ID.
+type Store struct {
+	Client *datastore.Client
+}
+
+// NewStore returns a Store backed by client.
+func NewStore(client *datastore.Client) *Store {
+	return &Store{Client: client}
+}
+
+func (s *Store) key(runID string) *datastore.Key {
+	return datastore.NameKey(Kind, runID, nil)
+}
+
+// Get returns the Checkpoint for runID, or a fresh StatePending Checkpoint
+// if none has been saved yet.
+func (s *Store) Get(ctx context.Context, runID string) (*Checkpoint, error) {
+	var cp Checkpoint
+	if err := s.Client.Get(ctx, s.key(runID), &cp); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return &Checkpoint{State: StatePending}, nil
+		}
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// Save persists cp for runID, stamping Updated to the current time.
+func (s *Store) Save(ctx context.Context, runID string, cp *Checkpoint) error {
+	cp.Updated = time.Now()
+	_, err := s.Client.Put(ctx, s.key(runID), cp)
+	return err
+}
@@ -0,0 +1,138 @@
+// Package wptarrow encodes and decodes the BigTable cell values written by
+// the load_data migration using Apache Arrow IPC, in place of the legacy
+// "<Test Status>#<Test Message>$<Sub Status>#<Sub Message>" positional
+// string format. Each cell holds a single-row Arrow record, which is
+// binary-safe (messages may contain "#" or "$") and directly consumable by
+// downstream BigQuery/Dataflow readers that understand Arrow IPC streams.
+package wptarrow
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/web-platform-tests/results-analysis/metrics"
+)
+
+// Schema is the fixed layout of every cell value: the test's status and
+// message, followed by the subtest's status and message (null when the
+// result has no subtest).
+var Schema = arrow.NewSchema(
+	[]arrow.Field{
+		{Name: "test_status", Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Uint8, ValueType: arrow.BinaryTypes.String}},
+		{Name: "test_message", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "sub_status", Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Uint8, ValueType: arrow.BinaryTypes.String}, Nullable: true},
+		{Name: "sub_message", Type: arrow.BinaryTypes.String, Nullable: true},
+	},
+	nil,
+)
+
+// Encode serializes a single (res, sub) result pair as a one-row Arrow IPC
+// stream. sub may be nil when res has no subtests.
+func Encode(res *metrics.TestResults, sub *metrics.SubTest) ([]byte, error) {
+	pool := memory.NewGoAllocator()
+	b := array.NewRecordBuilder(pool, Schema)
+	defer b.Release()
+
+	testStatus := b.Field(0).(*array.BinaryDictionaryBuilder)
+	if err := testStatus.AppendString(res.Status); err != nil {
+		return nil, fmt.Errorf("wptarrow: appending test_status: %w", err)
+	}
+
+	testMessage := b.Field(1).(*array.StringBuilder)
+	if res.Message != nil {
+		testMessage.Append(*res.Message)
+	} else {
+		testMessage.AppendNull()
+	}
+
+	subStatus := b.Field(2).(*array.BinaryDictionaryBuilder)
+	subMessage := b.Field(3).(*array.StringBuilder)
+	if sub != nil {
+		if err := subStatus.AppendString(sub.Status); err != nil {
+			return nil, fmt.Errorf("wptarrow: appending sub_status: %w", err)
+		}
+		if sub.Message != nil {
+			subMessage.Append(*sub.Message)
+		} else {
+			subMessage.AppendNull()
+		}
+	} else {
+		subStatus.AppendNull()
+		subMessage.AppendNull()
+	}
+
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w, err := ipc.NewWriter(&buf, ipc.WithSchema(Schema), ipc.WithAllocator(pool))
+	if err != nil {
+		return nil, fmt.Errorf("wptarrow: creating IPC writer: %w", err)
+	}
+	if err := w.Write(rec); err != nil {
+		return nil, fmt.Errorf("wptarrow: writing record: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("wptarrow: closing IPC writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Result is the decoded form of a single cell value, mirroring the fields
+// of metrics.TestResults/metrics.SubTest that were encoded.
+type Result struct {
+	TestStatus  string
+	TestMessage *string
+	HasSubtest  bool
+	SubStatus   string
+	SubMessage  *string
+}
+
+// Decode reconstructs a Result from an Arrow IPC cell value written by
+// Encode.
+func Decode(data []byte) (*Result, error) {
+	pool := memory.NewGoAllocator()
+	r, err := ipc.NewReader(bytes.NewReader(data), ipc.WithSchema(Schema), ipc.WithAllocator(pool))
+	if err != nil {
+		return nil, fmt.Errorf("wptarrow: creating IPC reader: %w", err)
+	}
+	defer r.Release()
+
+	if !r.Next() {
+		return nil, fmt.Errorf("wptarrow: no record in IPC stream")
+	}
+	rec := r.Record()
+
+	testStatus, _ := dictValue(rec.Column(0).(*array.Dictionary), 0)
+	res := &Result{
+		TestStatus:  testStatus,
+		TestMessage: stringValue(rec.Column(1).(*array.String), 0),
+	}
+
+	if subStatus, ok := dictValue(rec.Column(2).(*array.Dictionary), 0); ok {
+		res.HasSubtest = true
+		res.SubStatus = subStatus
+		res.SubMessage = stringValue(rec.Column(3).(*array.String), 0)
+	}
+
+	return res, nil
+}
+
+func dictValue(col *array.Dictionary, row int) (string, bool) {
+	if col.IsNull(row) {
+		return "", false
+	}
+	return col.Dictionary().(*array.String).Value(col.GetValueIndex(row)), true
+}
+
+func stringValue(col *array.String, row int) *string {
+	if col.IsNull(row) {
+		return nil
+	}
+	v := col.Value(row)
+	return &v
+}
@@ -0,0 +1,204 @@
+// Package stats records operation latencies for load_data's benchmarking
+// mode: a log-linear histogram per operation (HTTP fetches, BigTable bulk
+// writes), periodic logging of running percentiles, and a final CSV dump.
+package stats
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Op names an instrumented operation.
+type Op string
+
+// Operations instrumented by load_data.
+const (
+	OpHTTPGet   Op = "http_get"
+	OpApplyBulk Op = "apply_bulk"
+)
+
+const (
+	histMin     = time.Microsecond
+	histMax     = 60 * time.Second
+	histBuckets = 100
+)
+
+// histogram is a log-linear latency histogram spanning [histMin, histMax]
+// across histBuckets buckets, each covering an equal fraction of the log
+// range. It trades precision for a small, fixed memory footprint.
+type histogram struct {
+	mu      sync.Mutex
+	counts  [histBuckets]int64
+	count   int64
+	sum     time.Duration
+	min     time.Duration
+	max     time.Duration
+	logMin  float64
+	logSpan float64
+}
+
+func newHistogram() *histogram {
+	logMin := math.Log(float64(histMin))
+	logMax := math.Log(float64(histMax))
+	return &histogram{logMin: logMin, logSpan: logMax - logMin}
+}
+
+func (h *histogram) record(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.count++
+	h.sum += d
+
+	h.counts[h.bucketFor(d)]++
+}
+
+func (h *histogram) bucketFor(d time.Duration) int {
+	if d < histMin {
+		return 0
+	}
+	if d > histMax {
+		return histBuckets - 1
+	}
+	frac := (math.Log(float64(d)) - h.logMin) / h.logSpan
+	b := int(frac * histBuckets)
+	if b < 0 {
+		b = 0
+	}
+	if b >= histBuckets {
+		b = histBuckets - 1
+	}
+	return b
+}
+
+// percentile returns the upper bound of the bucket containing the p'th
+// percentile (0 < p <= 1) of recorded samples.
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	target := int64(math.Ceil(p * float64(h.count)))
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= target {
+			frac := float64(i+1) / histBuckets
+			return time.Duration(math.Exp(h.logMin + frac*h.logSpan))
+		}
+	}
+	return h.max
+}
+
+// Summary is a point-in-time snapshot of an operation's recorded latencies.
+type Summary struct {
+	Op    Op
+	Count int64
+	Min   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// Recorder tracks per-Op latency histograms. A Recorder is safe for
+// concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	hists map[Op]*histogram
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{hists: make(map[Op]*histogram)}
+}
+
+// Record adds a latency sample for op.
+func (r *Recorder) Record(op Op, d time.Duration) {
+	r.mu.Lock()
+	h, ok := r.hists[op]
+	if !ok {
+		h = newHistogram()
+		r.hists[op] = h
+	}
+	r.mu.Unlock()
+
+	h.record(d)
+}
+
+// Since is a convenience for Record(op, time.Since(start)).
+func (r *Recorder) Since(op Op, start time.Time) {
+	r.Record(op, time.Since(start))
+}
+
+// Snapshot returns a Summary per instrumented Op, ordered by Op name.
+func (r *Recorder) Snapshot() []Summary {
+	r.mu.Lock()
+	ops := make([]Op, 0, len(r.hists))
+	hists := make(map[Op]*histogram, len(r.hists))
+	for op, h := range r.hists {
+		ops = append(ops, op)
+		hists[op] = h
+	}
+	r.mu.Unlock()
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+
+	summaries := make([]Summary, 0, len(ops))
+	for _, op := range ops {
+		h := hists[op]
+		h.mu.Lock()
+		count := h.count
+		var mean time.Duration
+		if count > 0 {
+			mean = h.sum / time.Duration(count)
+		}
+		summary := Summary{
+			Op:    op,
+			Count: count,
+			Min:   h.min,
+			Mean:  mean,
+			Max:   h.max,
+			P50:   h.percentile(0.50),
+			P90:   h.percentile(0.90),
+			P99:   h.percentile(0.99),
+		}
+		h.mu.Unlock()
+
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// LogSummaries logs the current Summary of every instrumented Op.
+func (r *Recorder) LogSummaries(logf func(format string, args ...interface{})) {
+	for _, s := range r.Snapshot() {
+		logf("INFO: Stats[%s]: count=%d min=%s mean=%s p50=%s p90=%s p99=%s max=%s",
+			s.Op, s.Count, s.Min, s.Mean, s.P50, s.P90, s.P99, s.Max)
+	}
+}
+
+// WriteCSV dumps the current Summary of every instrumented Op as CSV with
+// header "op,count,min,p50,p90,p99,max,mean".
+func (r *Recorder) WriteCSV(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "op,count,min,p50,p90,p99,max,mean"); err != nil {
+		return err
+	}
+	for _, s := range r.Snapshot() {
+		_, err := fmt.Fprintf(w, "%s,%d,%s,%s,%s,%s,%s,%s\n",
+			s.Op, s.Count, s.Min, s.P50, s.P90, s.P99, s.Max, s.Mean)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,20 +1,41 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"flag"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"cloud.google.com/go/bigtable"
 	"cloud.google.com/go/datastore"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/cenkalti/backoff"
 	"github.com/web-platform-tests/results-analysis/metrics"
+	"github.com/web-platform-tests/wpt.fyi/grid/load/bigtable/checkpoint"
+	"github.com/web-platform-tests/wpt.fyi/grid/load/bigtable/encoding/wptarrow"
+	"github.com/web-platform-tests/wpt.fyi/grid/load/bigtable/internal/stats"
+	"github.com/web-platform-tests/wpt.fyi/grid/load/bigtable/sink"
 	"github.com/web-platform-tests/wpt.fyi/shared"
 	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/idtoken"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -25,7 +46,8 @@ import (
 // RowID: <Test ID / file name>:<Subtest ID>
 // Column Family: runs
 // Columns: <Browser ID>@<Long WPT Hash>#<TestRun CreatedAt UTC RFC3339>
-// Values: <Test Status>#<Test Message>$<Sub Status>#<Sub Message>
+// Values: legacy encoding is <Test Status>#<Test Message>$<Sub Status>#<Sub Message>;
+//         arrow encoding is a single-row Arrow IPC stream, see wptarrow.Schema.
 
 var projectID *string
 var inputGcsBucket *string
@@ -33,6 +55,25 @@ var gcpCredentialsFile *string
 var outputBTInstanceID *string
 var outputBTTableID *string
 var outputBTFamily *string
+var valueEncoding *string
+var outputBackend *string
+var azureAccountName *string
+var azureAccountKey *string
+var azureContainer *string
+var s3Bucket *string
+var s3Region *string
+var s3KeyPrefix *string
+var localOutputPath *string
+var csvOutput *string
+var runFor *time.Duration
+var poolSize *int
+var httpTimeout *time.Duration
+var httpMaxIdleConnsPerHost *int
+var httpQPS *float64
+var httpConcurrency *int64
+var httpAuthMode *string
+var httpAuthAudience *string
+var httpAuthBearerFile *string
 
 func init() {
 	projectID = flag.String("project_id", "wptdashboard", "Google Cloud Platform project id")
@@ -41,22 +82,55 @@ func init() {
 	outputBTInstanceID = flag.String("output_bt_instance_id", "wpt-results-matrix", "Output BigTable instance ID")
 	outputBTTableID = flag.String("output_bt_table_id", "wpt-results-per-test-wide", "Output BigTable table ID")
 	outputBTFamily = flag.String("output_bt_family", "runs", "Output BigTable column family for test results")
+	valueEncoding = flag.String("value_encoding", "legacy", "Cell value encoding to write: \"legacy\" (positional \"#\"/\"$\" string) or \"arrow\" (Arrow IPC, see wptarrow package)")
+
+	outputBackend = flag.String("output_backend", "bigtable", "Output sink to write test results to: \"bigtable\", \"azure\", \"s3\", or \"local\"")
+	azureAccountName = flag.String("azure_account_name", "", "Azure Storage account name (output_backend=azure)")
+	azureAccountKey = flag.String("azure_account_key", "", "Azure Storage account key (output_backend=azure)")
+	azureContainer = flag.String("azure_container", "wpt-results", "Azure Blob container name (output_backend=azure)")
+	s3Bucket = flag.String("s3_bucket", "wpt-results", "S3 bucket name (output_backend=s3)")
+	s3Region = flag.String("s3_region", "us-west-2", "AWS region for the S3 bucket (output_backend=s3)")
+	s3KeyPrefix = flag.String("s3_key_prefix", "wpt-results-per-test-wide", "S3 object key prefix (output_backend=s3)")
+	localOutputPath = flag.String("local_output_path", "wpt-results.jsonl", "Path to the JSON lines file to write to (output_backend=local)")
+
+	csvOutput = flag.String("csv_output", "", "If set, dump final per-operation latency histograms (op,count,min,p50,p90,p99,max,mean) to this CSV file on exit")
+	runFor = flag.Duration("run_for", 0, "If non-zero, stop accepting new runs once this long has elapsed since startup")
+	poolSize = flag.Int("pool_size", 0, "If non-zero, size of the gRPC connection pool used for the BigTable client (output_backend=bigtable)")
+
+	httpTimeout = flag.Duration("http_timeout", 30*time.Second, "Timeout for each RawResultsURL fetch")
+	httpMaxIdleConnsPerHost = flag.Int("http_max_idle_conns_per_host", 100, "Max idle keep-alive connections per RawResultsURL host")
+	httpQPS = flag.Float64("http_qps", 0, "If non-zero, a ceiling on RawResultsURL fetches per second, shared across all runs")
+	httpConcurrency = flag.Int64("http_concurrency", 100, "Max number of concurrent RawResultsURL fetches, independent of Bigtable write concurrency")
+	httpAuthMode = flag.String("http_auth_mode", "none", "Auth to attach to RawResultsURL fetches: \"none\", \"gcp_id_token\", or \"bearer_file\"")
+	httpAuthAudience = flag.String("http_auth_audience", "", "OIDC audience to mint ID tokens for (http_auth_mode=gcp_id_token)")
+	httpAuthBearerFile = flag.String("http_auth_bearer_file", "", "Path to a file containing a bearer token to send as \"Authorization: Bearer ...\" (http_auth_mode=bearer_file)")
 }
 
+// shutdownGrace is how long in-flight runs are given to flush buffered
+// writes after a SIGINT/SIGTERM before their write context is also
+// cancelled.
+const shutdownGrace = 25 * time.Second
+
 var numConcurrentRuns = int64(100)
 var maxMutationsPerBatch = 100000
 var maxHeapAlloc = uint64(4.5e+10)
 var monitorSleep = 2 * time.Second
 
-func monitor() {
-	var stats runtime.MemStats
+// checkpointSaveInterval is how many rows are written between periodic
+// checkpoint saves during a run's write loop, so a resumed run only
+// re-writes a bounded number of rows after a mid-write crash.
+const checkpointSaveInterval = 1000
+
+func monitor(recorder *stats.Recorder) {
+	var memStats runtime.MemStats
 	for {
-		runtime.ReadMemStats(&stats)
-		if stats.HeapAlloc > maxHeapAlloc {
+		runtime.ReadMemStats(&memStats)
+		if memStats.HeapAlloc > maxHeapAlloc {
 			log.Fatal("ERRO: Out of memory")
 		} else {
-			log.Printf("INFO: Monitor: %d heap-allocated bytes OK", stats.HeapAlloc)
+			log.Printf("INFO: Monitor: %d heap-allocated bytes OK", memStats.HeapAlloc)
 		}
+		recorder.LogSummaries(log.Printf)
 		time.Sleep(monitorSleep)
 	}
 }
@@ -97,127 +171,448 @@ func colID(run shared.TestRun) string {
 	return runID(run)
 }
 
+// encodeValue renders the value for a single (res, sub) cell write under
+// the configured -value_encoding. sub is nil when res has no subtests.
+func encodeValue(res *metrics.TestResults, sub *metrics.SubTest) ([]byte, error) {
+	switch *valueEncoding {
+	case "arrow":
+		return wptarrow.Encode(res, sub)
+	case "legacy":
+		return legacyEncodeValue(res, sub), nil
+	default:
+		return nil, fmt.Errorf("unknown -value_encoding %q", *valueEncoding)
+	}
+}
+
+// legacyEncodeValue reproduces the original positional "#"/"$" cell format.
+func legacyEncodeValue(res *metrics.TestResults, sub *metrics.SubTest) []byte {
+	testPart := res.Status
+	if res.Message != nil && *res.Message != "" {
+		testPart += "#" + *res.Message
+	}
+	if sub == nil {
+		return []byte(testPart)
+	}
+
+	subPart := sub.Status
+	if sub.Message != nil && *sub.Message != "" {
+		subPart += "#" + *sub.Message
+	}
+	return []byte(testPart + "$" + subPart)
+}
+
+// rateLimitedTransport throttles RoundTrip to limiter's token rate,
+// independent of how many fetches are concurrently in flight.
+type rateLimitedTransport struct {
+	limiter *rate.Limiter
+	base    http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// bearerTokenTransport attaches a static bearer token to every request.
+type bearerTokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *bearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(req)
+}
+
+// newHTTPClient builds the *http.Client used for RawResultsURL fetches,
+// per -http_timeout, -http_max_idle_conns_per_host, -http_qps, and
+// -http_auth_mode.
+func newHTTPClient(ctx context.Context) (*http.Client, error) {
+	var rt http.RoundTripper = &http.Transport{
+		MaxIdleConnsPerHost: *httpMaxIdleConnsPerHost,
+	}
+
+	switch *httpAuthMode {
+	case "none":
+		// No auth to attach.
+	case "bearer_file":
+		token, err := ioutil.ReadFile(*httpAuthBearerFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading -http_auth_bearer_file: %w", err)
+		}
+		rt = &bearerTokenTransport{token: strings.TrimSpace(string(token)), base: rt}
+	case "gcp_id_token":
+		idClient, err := idtoken.NewClient(ctx, *httpAuthAudience,
+			option.WithCredentialsFile(*gcpCredentialsFile),
+			option.WithHTTPClient(&http.Client{Transport: rt}))
+		if err != nil {
+			return nil, fmt.Errorf("creating gcp_id_token client: %w", err)
+		}
+		rt = idClient.Transport
+	default:
+		return nil, fmt.Errorf("unknown -http_auth_mode %q", *httpAuthMode)
+	}
+
+	if *httpQPS > 0 {
+		rt = &rateLimitedTransport{limiter: rate.NewLimiter(rate.Limit(*httpQPS), 1), base: rt}
+	}
+
+	return &http.Client{Transport: rt, Timeout: *httpTimeout}, nil
+}
+
+func isRetryableHTTPStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+func newHTTPBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 500 * time.Millisecond
+	b.MaxInterval = 2 * time.Minute
+	b.MaxElapsedTime = 15 * time.Minute
+	return b
+}
+
+// fetchReport downloads and decodes the TestResultsReport at url, retrying
+// retryable failures (network errors, HTTP 429/5xx) with jittered
+// exponential backoff. Bytes already downloaded are kept across retries
+// and a Range request picks up where the previous attempt left off.
+func fetchReport(ctx context.Context, client *http.Client, url string, recorder *stats.Recorder) (*metrics.TestResultsReport, error) {
+	var buf bytes.Buffer
+
+	operation := func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		if buf.Len() > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", buf.Len()))
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		recorder.Since(stats.OpHTTPGet, start)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if isRetryableHTTPStatus(resp.StatusCode) {
+			return fmt.Errorf("retryable HTTP status %d from %q", resp.StatusCode, url)
+		}
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+			return backoff.Permanent(fmt.Errorf("non-OK HTTP status %d from %q", resp.StatusCode, url))
+		}
+		if resp.StatusCode == http.StatusOK && buf.Len() > 0 {
+			// The server ignored our Range request and sent the full body
+			// again; discard the partial download instead of appending to it.
+			buf.Reset()
+		}
+
+		if _, err := io.Copy(&buf, resp.Body); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if err := backoff.Retry(operation, backoff.WithContext(newHTTPBackOff(), ctx)); err != nil {
+		return nil, err
+	}
+
+	var report metrics.TestResultsReport
+	if err := json.NewDecoder(&buf).Decode(&report); err != nil {
+		return nil, fmt.Errorf("decoding report from %q: %w", url, err)
+	}
+	return &report, nil
+}
+
+// azblobServiceURL builds the container URL for an Azure Storage account.
+func azblobServiceURL(accountName, container string) url.URL {
+	u, err := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net/%s", accountName, container))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return *u
+}
+
+// newSinkFactory builds the sink.Factory selected by -output_backend.
+func newSinkFactory(ctx context.Context, ts bigtable.Timestamp, recorder *stats.Recorder) (sink.Factory, error) {
+	switch *outputBackend {
+	case "bigtable":
+		var btOpts []option.ClientOption
+		btOpts = append(btOpts, option.WithCredentialsFile(*gcpCredentialsFile))
+		if *poolSize > 0 {
+			btOpts = append(btOpts, option.WithGRPCConnectionPool(*poolSize))
+		}
+		btClient, err := bigtable.NewClient(ctx, *projectID, *outputBTInstanceID, btOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("creating bigtable client: %w", err)
+		}
+		return &sink.BigtableFactory{
+			Table:                btClient.Open(*outputBTTableID),
+			Family:               *outputBTFamily,
+			Timestamp:            ts,
+			MaxMutationsPerBatch: maxMutationsPerBatch,
+			OnApplyBulk:          func(d time.Duration) { recorder.Record(stats.OpApplyBulk, d) },
+		}, nil
+
+	case "azure":
+		credential, err := azblob.NewSharedKeyCredential(*azureAccountName, *azureAccountKey)
+		if err != nil {
+			return nil, fmt.Errorf("creating azure credential: %w", err)
+		}
+		pipeline := azblob.NewPipeline(credential, azblob.PipelineOptions{})
+		containerURL := azblob.NewContainerURL(
+			azblobServiceURL(*azureAccountName, *azureContainer),
+			pipeline)
+		return &sink.AzureBlobFactory{ContainerURL: containerURL}, nil
+
+	case "s3":
+		sess, err := session.NewSession(&aws.Config{Region: aws.String(*s3Region)})
+		if err != nil {
+			return nil, fmt.Errorf("creating AWS session: %w", err)
+		}
+		return &sink.S3Factory{
+			Uploader:      s3manager.NewUploader(sess),
+			Bucket:        *s3Bucket,
+			KeyPrefix:     *s3KeyPrefix,
+			MaxBatchItems: maxMutationsPerBatch,
+		}, nil
+
+	case "local":
+		return &sink.LocalFactory{Path: *localOutputPath}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown -output_backend %q", *outputBackend)
+	}
+}
+
 func main() {
 	log.SetFlags(log.LstdFlags | log.Llongfile | log.LUTC)
 	flag.Parse()
 
-	go monitor()
+	recorder := stats.NewRecorder()
+	go monitor(recorder)
 
-	ctx := context.Background()
-	dsClient, err := datastore.NewClient(ctx, *projectID, option.WithCredentialsFile(*gcpCredentialsFile))
+	// runCtx gates new work (semaphore acquisitions, HTTP fetches) and is
+	// cancelled as soon as a shutdown signal arrives. writeCtx gates
+	// buffered sink writes and is only cancelled after shutdownGrace, so
+	// in-flight runs get a chance to flush before being cut off.
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	defer cancelRun()
+	writeCtx, cancelWrite := context.WithCancel(context.Background())
+	defer cancelWrite()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		log.Printf("WARN: Received %v, draining in-flight runs (grace period %s)", sig, shutdownGrace)
+		cancelRun()
+		time.AfterFunc(shutdownGrace, cancelWrite)
+	}()
+
+	dsClient, err := datastore.NewClient(runCtx, *projectID, option.WithCredentialsFile(*gcpCredentialsFile))
 	if err != nil {
 		log.Fatal(err)
 	}
+	checkpointStore := checkpoint.NewStore(dsClient)
 
-	btClient, err := bigtable.NewClient(ctx, *projectID, *outputBTInstanceID, option.WithCredentialsFile(*gcpCredentialsFile))
+	httpClient, err := newHTTPClient(runCtx)
 	if err != nil {
 		log.Fatal(err)
 	}
-	tbl := btClient.Open(*outputBTTableID)
+
 	ts := bigtable.Now()
+	sinkFactory, err := newSinkFactory(runCtx, ts, recorder)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *csvOutput != "" {
+		defer func() {
+			f, err := os.Create(*csvOutput)
+			if err != nil {
+				log.Printf("ERRO: Failed to create -csv_output file %q: %v", *csvOutput, err)
+				return
+			}
+			defer f.Close()
+			if err := recorder.WriteCSV(f); err != nil {
+				log.Printf("ERRO: Failed to write -csv_output file %q: %v", *csvOutput, err)
+			}
+		}()
+	}
 
-	_, runs := getRuns(ctx, dsClient)
-	sem := semaphore.NewWeighted(numConcurrentRuns)
+	_, runs := getRuns(runCtx, dsClient)
+	// httpSem bounds concurrent RawResultsURL fetches; writeSem separately
+	// bounds concurrent sink writes, so download fan-out can be tuned
+	// independently of write fan-out (downloads are often the bottleneck
+	// when results live behind auth).
+	httpSem := semaphore.NewWeighted(*httpConcurrency)
+	writeSem := semaphore.NewWeighted(numConcurrentRuns)
+	var wg sync.WaitGroup
+	started := 0
+
+	var deadline time.Time
+	if *runFor > 0 {
+		deadline = time.Now().Add(*runFor)
+	}
 	for _, run := range runs {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			log.Printf("WARN: -run_for elapsed, not starting %d remaining runs", len(runs)-started)
+			break
+		}
+		if runCtx.Err() != nil {
+			log.Printf("WARN: Shutting down, not starting remaining runs")
+			break
+		}
+
+		wg.Add(1)
+		started++
 		go func(run shared.TestRun) {
-			sem.Acquire(ctx, 1)
-			defer sem.Release(1)
+			defer wg.Done()
 
-			resp, err := http.Get(run.RawResultsURL)
+			id := runID(run)
+			cp, err := checkpointStore.Get(runCtx, id)
 			if err != nil {
-				log.Printf("WARN: Failed to load raw results from \"%s\" for %v", run.RawResultsURL, run)
+				// Get only returns an error for something other than "no
+				// checkpoint yet" (e.g. a transient Datastore read failure).
+				// Treating that as "start fresh" risks silently reprocessing
+				// an already-done run and writing a duplicate cell version
+				// at a new bigtable.Now() timestamp, so bail on this run
+				// instead and let a future invocation retry it.
+				log.Printf("ERRO: Failed to load checkpoint for %s, skipping run: %v", id, err)
 				return
 			}
-			defer resp.Body.Close()
-			if resp.StatusCode != http.StatusOK {
-				log.Printf("WARN: Non-OK HTTP status code of %d from \"%s\" for %v", resp.StatusCode, run.RawResultsURL, run)
+			if cp.State == checkpoint.StateDone {
+				log.Printf("INFO: Skipping already-done run %s", id)
 				return
 			}
-			data, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				log.Printf("WARN: Failed to read contents of \"%s\" for %v", run.RawResultsURL, run)
+			cp.Attempts++
+
+			if err := httpSem.Acquire(runCtx, 1); err != nil {
+				log.Printf("WARN: Not fetching run %s: %v", id, err)
+				return
+			}
+			// Run goroutines are all spawned up front, so -run_for is
+			// enforced here, where a run is actually started.
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				httpSem.Release(1)
+				log.Printf("WARN: -run_for elapsed, not starting run %s", id)
 				return
 			}
-			var report metrics.TestResultsReport
-			err = json.Unmarshal(data, &report)
+			report, err := fetchReport(runCtx, httpClient, run.RawResultsURL, recorder)
+			httpSem.Release(1)
 			if err != nil {
-				log.Printf("WARN: Failed to unmarshal JSON from \"%s\" for %v", run.RawResultsURL, run)
+				log.Printf("WARN: Failed to fetch raw results from \"%s\" for %v: %v", run.RawResultsURL, run, err)
+				cp.State = checkpoint.StateFailed
+				cp.LastError = err.Error()
+				if err := checkpointStore.Save(context.Background(), id, cp); err != nil {
+					log.Printf("WARN: Failed to save checkpoint for %s: %v", id, err)
+				}
 				return
 			}
 			if len(report.Results) == 0 {
-				log.Printf("WARN: Empty report from %s (%s)", runID(run), run.RawResultsURL)
+				log.Printf("WARN: Empty report from %s (%s)", id, run.RawResultsURL)
 				return
 			}
 
-			log.Printf("INFO: Gathering %d test results", len(report.Results))
-			muts := make([]*bigtable.Mutation, 0)
-			rows := make([]string, 0)
-			set := func(row, family, column string, ts bigtable.Timestamp, value []byte) {
-				mut := bigtable.NewMutation()
-				if len(muts) == maxMutationsPerBatch {
-					rs := rows[0:]
-					ms := muts[0:]
-					errs, err := tbl.ApplyBulk(ctx, rs, ms)
-					if len(errs) > 0 {
-						log.Printf("ERRO: Some writes from BigTable bulk write failed: %v", errs)
-					} else if err != nil {
-						log.Printf("ERRO: BigTable bulk write failed: %v", err)
-					} else {
-						log.Printf("INFO: BigTable bulk write success (%d mutations to row %s)", len(ms), rs[0])
-					}
+			cp.State = checkpoint.StateDownloaded
+			if err := checkpointStore.Save(runCtx, id, cp); err != nil {
+				log.Printf("WARN: Failed to save checkpoint for %s: %v", id, err)
+			}
 
-					muts = make([]*bigtable.Mutation, 0)
-					rows = make([]string, 0)
-				}
+			if err := writeSem.Acquire(writeCtx, 1); err != nil {
+				log.Printf("WARN: Not writing run %s: %v", id, err)
+				return
+			}
+			defer writeSem.Release(1)
+
+			log.Printf("INFO: Gathering %d test results", len(report.Results))
+			s := sinkFactory.New()
 
-				muts = append(muts, mut)
-				rows = append(rows, row)
+			// Sort so a resumed run can seek past rows already written
+			// (rewriting a cell is harmless, but skipping it is faster).
+			sort.Slice(report.Results, func(i, j int) bool { return report.Results[i].Test < report.Results[j].Test })
 
-				mut.Set(family, column, ts, value)
+			cp.State = checkpoint.StateWriting
+			if err := checkpointStore.Save(runCtx, id, cp); err != nil {
+				log.Printf("WARN: Failed to save checkpoint for %s: %v", id, err)
 			}
 
-			for _, res := range report.Results {
-				if len(res.Subtests) == 0 {
+			// A Put/Flush error aborts the run: cp.LastRowWritten must not
+			// advance past a row that failed to write, or a resumed run
+			// would skip it forever believing it was already migrated. Sinks
+			// buffer Puts, so it only advances after a successful Flush.
+			var writeErr error
+			lastRow := cp.LastRowWritten
+		rowLoop:
+			for i, res := range report.Results {
+				row := rowID(res, nil)
+				if cp.LastRowWritten != "" && row <= cp.LastRowWritten {
+					continue
+				}
 
-					if res.Message != nil && *res.Message != "" {
-						set(rowID(res, nil), *outputBTFamily, colID(run), ts, []byte(res.Status+"#"+*res.Message))
-					} else {
-						set(rowID(res, nil), *outputBTFamily, colID(run), ts, []byte(res.Status))
+				if len(res.Subtests) == 0 {
+					value, err := encodeValue(res, nil)
+					if err != nil {
+						log.Printf("ERRO: Failed to encode value for %s: %v", row, err)
+						continue
+					}
+					if err := s.Put(writeCtx, row, colID(run), value); err != nil {
+						writeErr = fmt.Errorf("writing %s: %w", row, err)
+						break rowLoop
 					}
 				} else {
 					for _, sub := range res.Subtests {
-						if res.Message != nil && *res.Message != "" {
-							if sub.Message != nil && *sub.Message != "" {
-								set(rowID(res, nil), *outputBTFamily, colID(run), ts, []byte(res.Status+"#"+*res.Message+"$"+sub.Status+"#"+*sub.Message))
-							} else {
-								set(rowID(res, nil), *outputBTFamily, colID(run), ts, []byte(res.Status+"#"+*res.Message+"$"+sub.Status))
-							}
-						} else {
-							if sub.Message != nil && *sub.Message != "" {
-								set(rowID(res, nil), *outputBTFamily, colID(run), ts, []byte(res.Status+"$"+sub.Status+"#"+*sub.Message))
-							} else {
-								set(rowID(res, nil), *outputBTFamily, colID(run), ts, []byte(res.Status+"$"+sub.Status))
-							}
+						value, err := encodeValue(res, sub)
+						if err != nil {
+							log.Printf("ERRO: Failed to encode value for %s: %v", row, err)
+							continue
+						}
+						if err := s.Put(writeCtx, row, colID(run), value); err != nil {
+							writeErr = fmt.Errorf("writing %s: %w", row, err)
+							break rowLoop
 						}
 					}
 				}
-			}
 
-			if len(muts) > 0 {
-				rs := rows[0:]
-				ms := muts[0:]
-				errs, err := tbl.ApplyBulk(ctx, rs, ms)
-				if len(errs) > 0 {
-					log.Printf("ERRO: Some writes from BigTable bulk write failed: %v", errs)
-				} else if err != nil {
-					log.Printf("ERRO: BigTable bulk write failed: %v", err)
-				} else {
-					log.Printf("INFO: BigTable bulk write success (%d mutations to row %s)", len(ms), rs[0])
+				lastRow = row
+
+				if (i+1)%checkpointSaveInterval == 0 {
+					if err := s.Flush(writeCtx); err != nil {
+						writeErr = fmt.Errorf("flushing through %s: %w", row, err)
+						break rowLoop
+					}
+					cp.LastRowWritten = lastRow
+					if err := checkpointStore.Save(runCtx, id, cp); err != nil {
+						log.Printf("WARN: Failed to save checkpoint for %s: %v", id, err)
+					}
 				}
 			}
+
+			if writeErr == nil {
+				writeErr = s.Flush(writeCtx)
+			}
+
+			if writeErr != nil {
+				log.Printf("ERRO: Sink write failed for %s: %v", id, writeErr)
+				cp.State = checkpoint.StateFailed
+				cp.LastError = writeErr.Error()
+			} else {
+				log.Printf("INFO: Sink flush success for %s", id)
+				cp.LastRowWritten = lastRow
+				cp.State = checkpoint.StateDone
+			}
+			if err := checkpointStore.Save(context.Background(), id, cp); err != nil {
+				log.Printf("WARN: Failed to save checkpoint for %s: %v", id, err)
+			}
 		}(run)
 	}
 
-	sem.Acquire(ctx, numConcurrentRuns)
+	wg.Wait()
 	log.Printf("INFO: Finished processing %d runs", len(runs))
+	recorder.LogSummaries(log.Printf)
 }
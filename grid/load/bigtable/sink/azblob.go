@@ -0,0 +1,64 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// AzureBlobFactory builds Sinks that write one append blob per row key,
+// appending a block per column write so multiple runs' cells for the same
+// test land in the same blob.
+type AzureBlobFactory struct {
+	ContainerURL azblob.ContainerURL
+}
+
+func (f *AzureBlobFactory) New() Sink {
+	return &azureBlobSink{factory: f, blobs: make(map[string]azblob.AppendBlobURL)}
+}
+
+type azureBlobSink struct {
+	factory *AzureBlobFactory
+	blobs   map[string]azblob.AppendBlobURL
+}
+
+func (s *azureBlobSink) blobFor(ctx context.Context, rowKey string) (azblob.AppendBlobURL, error) {
+	if blob, ok := s.blobs[rowKey]; ok {
+		return blob, nil
+	}
+
+	blob := s.factory.ContainerURL.NewAppendBlobURL(rowKey)
+	// If-None-Match: * makes Create fail with BlobAlreadyExists instead of
+	// replacing a blob that earlier runs already appended to.
+	_, err := blob.Create(ctx, azblob.BlobHTTPHeaders{}, azblob.Metadata{}, azblob.BlobAccessConditions{
+		ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfNoneMatch: azblob.ETagAny},
+	})
+	if err != nil {
+		if stgErr, ok := err.(azblob.StorageError); !ok || stgErr.ServiceCode() != azblob.ServiceCodeBlobAlreadyExists {
+			return azblob.AppendBlobURL{}, fmt.Errorf("creating append blob %q: %w", rowKey, err)
+		}
+	}
+
+	s.blobs[rowKey] = blob
+	return blob, nil
+}
+
+func (s *azureBlobSink) Put(ctx context.Context, rowKey, column string, value []byte) error {
+	blob, err := s.blobFor(ctx, rowKey)
+	if err != nil {
+		return err
+	}
+
+	block := append([]byte(column+"="), value...)
+	if _, err := blob.AppendBlock(ctx, bytes.NewReader(block), azblob.AppendBlobAccessConditions{}, nil, azblob.ClientProvidedKeyOptions{}); err != nil {
+		return fmt.Errorf("appending block for %q/%q: %w", rowKey, column, err)
+	}
+	return nil
+}
+
+// Flush is a no-op: every Put is applied directly as an append block.
+func (s *azureBlobSink) Flush(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,110 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/cenkalti/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryable bigtable ApplyBulk errors: the write can simply be resent,
+// since cell Set mutations are idempotent.
+func isRetryableBigtableErr(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+func newBigtableBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 500 * time.Millisecond
+	b.MaxInterval = 2 * time.Minute
+	b.MaxElapsedTime = 15 * time.Minute
+	return b
+}
+
+// BigtableFactory builds Sinks that batch mutations and apply them with
+// tbl.ApplyBulk, matching the original load_data write path.
+type BigtableFactory struct {
+	Table                *bigtable.Table
+	Family               string
+	Timestamp            bigtable.Timestamp
+	MaxMutationsPerBatch int
+
+	// OnApplyBulk, if set, is called with the latency of every
+	// tbl.ApplyBulk call, for benchmarking/observability.
+	OnApplyBulk func(time.Duration)
+}
+
+func (f *BigtableFactory) New() Sink {
+	return &bigtableSink{factory: f}
+}
+
+type bigtableSink struct {
+	factory *BigtableFactory
+	muts    []*bigtable.Mutation
+	rows    []string
+}
+
+func (s *bigtableSink) Put(ctx context.Context, rowKey, column string, value []byte) error {
+	mut := bigtable.NewMutation()
+	mut.Set(s.factory.Family, column, s.factory.Timestamp, value)
+	s.muts = append(s.muts, mut)
+	s.rows = append(s.rows, rowKey)
+
+	if len(s.muts) >= s.factory.MaxMutationsPerBatch {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+func (s *bigtableSink) Flush(ctx context.Context) error {
+	if len(s.muts) == 0 {
+		return nil
+	}
+
+	rows, muts := s.rows, s.muts
+	s.rows = nil
+	s.muts = nil
+
+	var errs []error
+	err := backoff.Retry(func() error {
+		start := time.Now()
+		var applyErr error
+		errs, applyErr = s.factory.Table.ApplyBulk(ctx, rows, muts)
+		if s.factory.OnApplyBulk != nil {
+			s.factory.OnApplyBulk(time.Since(start))
+		}
+		if applyErr != nil {
+			if isRetryableBigtableErr(applyErr) {
+				return applyErr
+			}
+			return backoff.Permanent(applyErr)
+		}
+
+		// ApplyBulk can report transient per-mutation failures (including
+		// codes.Unavailable/codes.DeadlineExceeded) through errs even when
+		// applyErr is nil, so those must be retried too.
+		for _, mutErr := range errs {
+			if mutErr == nil {
+				continue
+			}
+			if !isRetryableBigtableErr(mutErr) {
+				return backoff.Permanent(fmt.Errorf("some writes from bigtable bulk write failed: %v", errs))
+			}
+			return fmt.Errorf("some writes from bigtable bulk write failed: %v", errs)
+		}
+		return nil
+	}, backoff.WithContext(newBigtableBackOff(), ctx))
+	if err != nil {
+		return fmt.Errorf("bigtable bulk write failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package sink
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// LocalFactory builds Sinks that append JSON lines to a single file on
+// disk, for dry-runs and local development without any cloud backend.
+type LocalFactory struct {
+	Path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// localRecord is one JSON line written per cell.
+type localRecord struct {
+	RowKey string `json:"row_key"`
+	Column string `json:"column"`
+	Value  string `json:"value"` // base64-encoded, values may be binary (e.g. Arrow IPC)
+}
+
+func (f *LocalFactory) New() Sink {
+	return &localSink{factory: f}
+}
+
+func (f *LocalFactory) open() (*os.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("opening local sink output %q: %w", f.Path, err)
+		}
+		f.file = file
+	}
+	return f.file, nil
+}
+
+type localSink struct {
+	factory *LocalFactory
+	buf     []localRecord
+}
+
+func (s *localSink) Put(ctx context.Context, rowKey, column string, value []byte) error {
+	s.buf = append(s.buf, localRecord{
+		RowKey: rowKey,
+		Column: column,
+		Value:  base64.StdEncoding.EncodeToString(value),
+	})
+	return nil
+}
+
+func (s *localSink) Flush(ctx context.Context) error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+
+	file, err := s.factory.open()
+	if err != nil {
+		return err
+	}
+
+	s.factory.mu.Lock()
+	defer s.factory.mu.Unlock()
+
+	enc := json.NewEncoder(file)
+	for _, rec := range s.buf {
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("writing local sink record: %w", err)
+		}
+	}
+	s.buf = nil
+	return nil
+}
@@ -0,0 +1,70 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3Factory builds Sinks that batch cell writes and upload each batch as a
+// set of objects via s3manager's multi-part, concurrent uploader. Objects
+// are keyed "<keyPrefix>/<rowKey>/<column>".
+type S3Factory struct {
+	Uploader      *s3manager.Uploader
+	Bucket        string
+	KeyPrefix     string
+	MaxBatchItems int
+}
+
+func (f *S3Factory) New() Sink {
+	return &s3Sink{factory: f}
+}
+
+type s3Item struct {
+	rowKey, column string
+	value          []byte
+}
+
+type s3Sink struct {
+	factory *S3Factory
+	items   []s3Item
+}
+
+func (s *s3Sink) Put(ctx context.Context, rowKey, column string, value []byte) error {
+	s.items = append(s.items, s3Item{rowKey: rowKey, column: column, value: value})
+	if len(s.items) >= s.factory.MaxBatchItems {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+func (s *s3Sink) Flush(ctx context.Context) error {
+	if len(s.items) == 0 {
+		return nil
+	}
+
+	items := s.items
+	s.items = nil
+
+	iter := &s3manager.UploadObjectsIterator{
+		Objects: make([]s3manager.BatchUploadObject, len(items)),
+	}
+	for i, item := range items {
+		key := fmt.Sprintf("%s/%s/%s", s.factory.KeyPrefix, item.rowKey, item.column)
+		iter.Objects[i] = s3manager.BatchUploadObject{
+			Object: &s3manager.UploadInput{
+				Bucket: aws.String(s.factory.Bucket),
+				Key:    aws.String(key),
+				Body:   bytes.NewReader(item.value),
+			},
+		}
+	}
+
+	if err := s.factory.Uploader.UploadWithIterator(ctx, iter); err != nil {
+		return fmt.Errorf("s3 batch upload failed: %w", err)
+	}
+	return nil
+}
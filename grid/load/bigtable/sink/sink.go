@@ -0,0 +1,25 @@
+// Package sink abstracts the destination of a load_data run's test result
+// cells, so the migration can target BigTable (the original behavior),
+// Azure Blob Storage, S3, or the local filesystem via the same write path.
+package sink
+
+import "context"
+
+// Sink receives cell writes for a single run and batches/flushes them to
+// a backing store. A Sink is not safe for concurrent use; callers create
+// one Sink per run via a Factory and use it from a single goroutine.
+type Sink interface {
+	// Put stages rowKey/column/value for writing. Implementations may
+	// buffer and flush automatically once a backend-specific batch size
+	// is reached.
+	Put(ctx context.Context, rowKey, column string, value []byte) error
+	// Flush writes any buffered cells and blocks until they land.
+	Flush(ctx context.Context) error
+}
+
+// Factory constructs a new Sink bound to a run. Factories hold the shared
+// backend client (BigTable table, blob container, S3 uploader, ...) and
+// are safe for concurrent use across runs; the Sinks they hand out are not.
+type Factory interface {
+	New() Sink
+}